@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Coder defines an interface for an error code's detail information: the
+// external-facing message, the transport status it maps to, and where to
+// find more detail about it.
+type Coder interface {
+	// Code returns the integer code of the coder.
+	Code() int
+
+	// String returns the external, user-safe display text for the coder.
+	String() string
+
+	// HTTPStatus returns the HTTP status code associated with the coder.
+	HTTPStatus() int
+
+	// GRPCStatus returns the gRPC status code associated with the coder.
+	GRPCStatus() codes.Code
+
+	// Reference returns a URL to the documentation describing this error in
+	// detail, or the empty string if there is none.
+	Reference() string
+}
+
+// Translator localizes a Coder's user-facing String() for a given BCP 47
+// language tag (e.g. "en-US", "zh-CN"). It returns ok == false when no
+// translation is available, in which case callers fall back to
+// coder.String().
+type Translator interface {
+	Translate(coder Coder, langTag string) (message string, ok bool)
+}
+
+// UnknownCode is the code of the default Coder returned by ParseCoder when
+// an error's code has not been registered, and the code Spawn/WithMessage
+// use to promote a bare error that isn't already a *withCode.
+const UnknownCode = 1
+
+type defaultCoder struct{}
+
+func (defaultCoder) Code() int              { return UnknownCode }
+func (defaultCoder) String() string         { return "An internal server error occurred" }
+func (defaultCoder) HTTPStatus() int        { return 500 }
+func (defaultCoder) GRPCStatus() codes.Code { return codes.Unknown }
+func (defaultCoder) Reference() string      { return "" }
+
+var (
+	mu         sync.RWMutex
+	registry   = map[int]Coder{UnknownCode: defaultCoder{}}
+	translator Translator
+	lang       string
+)
+
+// Register registers coder under coder.Code() for later retrieval by
+// ParseCoder. It panics if coder.Code() is UnknownCode, or if that code has
+// already been registered; use MustRegister to overwrite an existing
+// registration.
+func Register(coder Coder) {
+	if coder.Code() == UnknownCode {
+		panic("errors: code '1' is reserved for the default coder")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[coder.Code()]; ok {
+		panic(fmt.Sprintf("errors: code '%d' is already registered", coder.Code()))
+	}
+	registry[coder.Code()] = coder
+}
+
+// MustRegister registers coder under coder.Code(), overwriting any Coder
+// previously registered with that code. It panics if coder.Code() is
+// UnknownCode.
+func MustRegister(coder Coder) {
+	if coder.Code() == UnknownCode {
+		panic("errors: code '1' is reserved for the default coder")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	registry[coder.Code()] = coder
+}
+
+// ParseCoder resolves err to its registered Coder by walking err's cause
+// chain for a *withCode. It returns the default Coder (code UnknownCode) if
+// err is nil, carries no registered code, or isn't a *withCode at all.
+func ParseCoder(err error) Coder {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	for e := err; e != nil; {
+		if w, ok := e.(*withCode); ok {
+			if coder, ok := registry[w.code]; ok {
+				return coder
+			}
+			return registry[UnknownCode]
+		}
+
+		causer, ok := e.(interface{ Cause() error })
+		if !ok {
+			break
+		}
+		e = causer.Cause()
+	}
+
+	return registry[UnknownCode]
+}
+
+// SetTranslator installs t as the Translator used to localize Coder messages
+// when formatting withCode errors. Pass nil to disable localization.
+func SetTranslator(t Translator) {
+	mu.Lock()
+	defer mu.Unlock()
+	translator = t
+}
+
+// SetLanguage sets the BCP 47 language tag passed to the installed
+// Translator. It has no effect until a Translator is installed via
+// SetTranslator.
+func SetLanguage(langTag string) {
+	mu.Lock()
+	defer mu.Unlock()
+	lang = langTag
+}
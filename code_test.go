@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	codeTestBase      = 910001
+	codeTestDuplicate = 910002
+)
+
+type codeTestCoder struct {
+	code int
+	msg  string
+	ref  string
+}
+
+func (c codeTestCoder) Code() int              { return c.code }
+func (c codeTestCoder) String() string         { return c.msg }
+func (c codeTestCoder) HTTPStatus() int        { return 400 }
+func (c codeTestCoder) GRPCStatus() codes.Code { return codes.InvalidArgument }
+func (c codeTestCoder) Reference() string      { return c.ref }
+
+func mustPanic(t *testing.T, what string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("%s: expected a panic, got none", what)
+		}
+	}()
+	f()
+}
+
+func TestRegisterPanicsOnUnknownCode(t *testing.T) {
+	mustPanic(t, "Register(code=UnknownCode)", func() {
+		Register(codeTestCoder{code: UnknownCode, msg: "nope"})
+	})
+}
+
+func TestRegisterPanicsOnDuplicateCode(t *testing.T) {
+	Register(codeTestCoder{code: codeTestDuplicate, msg: "first"})
+	mustPanic(t, "Register(duplicate code)", func() {
+		Register(codeTestCoder{code: codeTestDuplicate, msg: "second"})
+	})
+}
+
+func TestMustRegisterOverwrites(t *testing.T) {
+	MustRegister(codeTestCoder{code: codeTestBase, msg: "first"})
+	MustRegister(codeTestCoder{code: codeTestBase, msg: "second"})
+
+	got := ParseCoder(New(codeTestBase, "boom"))
+	if got.String() != "second" {
+		t.Fatalf("ParseCoder(...).String() = %q, want %q (MustRegister should overwrite)", got.String(), "second")
+	}
+}
+
+func TestMustRegisterPanicsOnUnknownCode(t *testing.T) {
+	mustPanic(t, "MustRegister(code=UnknownCode)", func() {
+		MustRegister(codeTestCoder{code: UnknownCode, msg: "nope"})
+	})
+}
+
+type upperTranslator struct{}
+
+func (upperTranslator) Translate(coder Coder, langTag string) (string, bool) {
+	if langTag != "shout" {
+		return "", false
+	}
+	return strings.ToUpper(coder.String()), true
+}
+
+func TestSetTranslatorChangesMessage(t *testing.T) {
+	MustRegister(codeTestCoder{code: codeTestBase, msg: "quiet message"})
+	defer func() {
+		SetTranslator(nil)
+		SetLanguage("")
+	}()
+
+	err := New(codeTestBase, "")
+
+	SetTranslator(nil)
+	SetLanguage("")
+	if got := err.Error(); !strings.Contains(got, "quiet message") {
+		t.Fatalf("Error() = %q, want it to contain the untranslated %q", got, "quiet message")
+	}
+
+	SetTranslator(upperTranslator{})
+	SetLanguage("shout")
+	if got := err.Error(); !strings.Contains(got, "QUIET MESSAGE") {
+		t.Fatalf("Error() = %q, want it to contain the translated %q", got, "QUIET MESSAGE")
+	}
+
+	SetLanguage("quiet")
+	if got := err.Error(); !strings.Contains(got, "quiet message") || strings.Contains(got, "QUIET MESSAGE") {
+		t.Fatalf("Error() = %q, want the untranslated text when the Translator returns ok=false", got)
+	}
+}
+
+func TestFormatPlusVShowsReference(t *testing.T) {
+	const ref = "https://example.com/errors/910001"
+	MustRegister(codeTestCoder{code: codeTestBase, msg: "boom", ref: ref})
+
+	err := New(codeTestBase, "detail")
+	got := fmt.Sprintf("%+v", err)
+	if !strings.Contains(got, "reference: "+ref) {
+		t.Fatalf("%%+v = %q, want it to contain %q", got, "reference: "+ref)
+	}
+	if !strings.Contains(got, "code: 910001") {
+		t.Fatalf("%%+v = %q, want it to contain the numeric code", got)
+	}
+}
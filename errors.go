@@ -93,14 +93,17 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
+	"io"
 )
 
 type withCode struct {
-	err   error  // 当前的error
-	code  int    // 错误码
-	msg   string // 在coder.String()基础上附加的消息
-	cause error  // 根因
+	err           error  // 当前的error
+	code          int    // 错误码
+	msg           string // 在coder.String()基础上附加的消息
+	cause         error  // 根因
+	maxStackDepth *int   // overrides jsonStackDepth for this error's JSON/slog output
 	*stack
 }
 
@@ -126,43 +129,60 @@ func Wrap(err error, code int) error {
 	}
 }
 
-// Spawn 构建一个新的withCode, 将传入的err作为cause
+// Spawn 构建一个新的withCode, 将传入的err作为cause. 若err不是*withCode,
+// 先通过Wrap(err, UnknownCode)将其提升为withCode, 而不是panic, 这样
+// Spawn可以安全地用在第三方error流入的API边界上。
 func Spawn(err error, code int, format string, args ...interface{}) error {
 	if err == nil {
 		return nil
 	}
-	_, ok := err.(*withCode)
+	cause, ok := err.(*withCode)
 	if !ok {
-		panic("err assert failed: not a 'withCode' type")
+		cause = Wrap(err, UnknownCode).(*withCode)
 	}
 
 	return &withCode{
 		err:   fmt.Errorf(format, args...),
 		code:  code,
-		cause: err,
+		cause: cause,
 		stack: callers(),
 	}
 }
 
-// WithMessage extend err's coder.String()
-func WithMessage(err error, message string) {
+// WithMessage returns a new error equivalent to err with message appended to
+// its coder.String() output. If err is not a *withCode it is first promoted
+// via Wrap(err, UnknownCode). Unlike WithMessageInPlace, err itself is never
+// mutated.
+func WithMessage(err error, message string) error {
 	if err == nil {
-		return
+		return nil
 	}
 	e, ok := err.(*withCode)
 	if !ok {
-		panic("err assert failed: not a 'withCode' type")
+		e = Wrap(err, UnknownCode).(*withCode)
 	}
 
-	if e.msg != "" {
-		e.msg = fmt.Sprintf("%s: %s", e.msg, message)
+	n := *e
+	if n.msg != "" {
+		n.msg = fmt.Sprintf("%s: %s", n.msg, message)
 	} else {
-		e.msg = message
+		n.msg = message
 	}
+	return &n
+}
+
+// WithMessagef is the Printf-style equivalent of WithMessage.
+func WithMessagef(err error, format string, args ...interface{}) error {
+	return WithMessage(err, fmt.Sprintf(format, args...))
 }
 
-// WithMessagef extend err's coder.String()
-func WithMessagef(err error, format string, args ...interface{}) {
+// WithMessageInPlace extends err's coder.String() by mutating err in place.
+//
+// Deprecated: mutating a shared error value is not goroutine-safe, and the
+// message is silently lost if err was stored as a copy rather than the
+// original *withCode pointer. Use WithMessage, which returns a new error,
+// instead. WithMessageInPlace will be removed in a future release.
+func WithMessageInPlace(err error, message string) {
 	if err == nil {
 		return
 	}
@@ -171,7 +191,6 @@ func WithMessagef(err error, format string, args ...interface{}) {
 		panic("err assert failed: not a 'withCode' type")
 	}
 
-	message := fmt.Sprintf(format, args...)
 	if e.msg != "" {
 		e.msg = fmt.Sprintf("%s: %s", e.msg, message)
 	} else {
@@ -179,14 +198,114 @@ func WithMessagef(err error, format string, args ...interface{}) {
 	}
 }
 
+// WithMessagefInPlace is the Printf-style equivalent of WithMessageInPlace.
+//
+// Deprecated: see WithMessageInPlace.
+func WithMessagefInPlace(err error, format string, args ...interface{}) {
+	WithMessageInPlace(err, fmt.Sprintf(format, args...))
+}
+
 // Error return the externally-safe error message.
 func (w *withCode) Error() string { return fmt.Sprintf("%v", w) }
 
+// message builds the externally-safe text for this error: the registered
+// coder's String(), localized by the installed Translator if any, followed
+// by w.err's own message (the format string passed to New, the wrapped
+// error passed to Wrap, or the context string passed to Spawn) and then any
+// message attached via WithMessage/WithMessagef.
+func (w *withCode) message() string {
+	coder := ParseCoder(w)
+	text := coder.String()
+
+	mu.RLock()
+	t := translator
+	l := lang
+	mu.RUnlock()
+	if t != nil {
+		if translated, ok := t.Translate(coder, l); ok {
+			text = translated
+		}
+	}
+
+	if w.err != nil {
+		text = fmt.Sprintf("%s: %s", text, w.err.Error())
+	}
+	if w.msg != "" {
+		text = fmt.Sprintf("%s: %s", text, w.msg)
+	}
+	return text
+}
+
+// Format implements fmt.Formatter.
+//
+//	%s    print the registered coder's user-facing message followed by the
+//	      error's own text, as built by message
+//	%v    see %s
+//	%+v   like %s, plus the numeric code, the coder's reference URL (if any)
+//	      and the full stack trace, recursing into the cause
+func (w *withCode) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			coder := ParseCoder(w)
+			fmt.Fprintf(s, "code: %d, message: %s", w.code, w.message())
+			if ref := coder.Reference(); ref != "" {
+				fmt.Fprintf(s, ", reference: %s", ref)
+			}
+			if w.stack != nil {
+				w.stack.Format(s, verb)
+			}
+			if w.cause != nil {
+				fmt.Fprintf(s, "\ncaused by: %+v", w.cause)
+			}
+			return
+		}
+		io.WriteString(s, w.message())
+	case 's':
+		io.WriteString(s, w.message())
+	case 'q':
+		fmt.Fprintf(s, "%q", w.message())
+	}
+}
+
 // Cause return the cause of the withCode error.
 func (w *withCode) Cause() error { return w.cause }
 
-// Unwrap provides compatibility for Go 1.13 error chains.
-func (w *withCode) Unwrap() error { return w.cause }
+// Unwrap provides compatibility for Go 1.13 error chains. It walks w.cause
+// before w.err, so a Spawn chain stays reachable by errors.Is/errors.As;
+// w.err is only consulted when there's no cause, which is what surfaces a
+// stdlib sentinel wrapped directly via Wrap (e.g. fs.ErrNotExist).
+func (w *withCode) Unwrap() error {
+	if w.cause != nil {
+		return w.cause
+	}
+	return w.err
+}
+
+// Is implements the Go 1.13 error-tree protocol used by errors.Is. It
+// reports a match when target is a *withCode carrying the same code, or
+// when target is identical to the error or cause this withCode wraps.
+func (w *withCode) Is(target error) bool {
+	if wc, ok := target.(*withCode); ok {
+		return wc.code == w.code
+	}
+	return target == w.err || target == w.cause
+}
+
+// As implements the Go 1.13 error-tree protocol used by errors.As. Matching
+// **withCode targets is already handled by errors.As's own reflection-based
+// assignability check before it ever calls As, so the only case left for
+// this method is a *Coder target, which it fills with ParseCoder(w): the
+// Coder registered for w's code, or the default Coder if that code is not
+// registered. Any other target type reports false.
+func (w *withCode) As(target interface{}) bool {
+	cm, ok := target.(*Coder)
+	if !ok {
+		return false
+	}
+	*cm = ParseCoder(w)
+	return true
+}
 
 // Cause returns the underlying cause of the error, if possible.
 // An error value has a cause if it implements the following
@@ -218,3 +337,42 @@ func Cause(err error) error {
 	}
 	return err
 }
+
+// Is reports whether any error in err's tree matches target. It mirrors the
+// standard library's errors.Is, which also drives *withCode's own Is method.
+func Is(err, target error) bool { return stderrors.Is(err, target) }
+
+// As finds the first error in err's tree that matches target, and if one is
+// found, sets target to that error value and returns true. It mirrors the
+// standard library's errors.As, which also drives *withCode's own As method.
+func As(err error, target interface{}) bool { return stderrors.As(err, target) }
+
+// Unwrap returns the result of calling the Unwrap method on err, if err's
+// type contains an Unwrap method returning error. It mirrors the standard
+// library's errors.Unwrap.
+func Unwrap(err error) error { return stderrors.Unwrap(err) }
+
+// IsCode reports whether any *withCode in err's wrap chain carries code,
+// descending into both single-error (Unwrap() error) and multi-error
+// (Unwrap() []error, e.g. *MultiError) links.
+func IsCode(err error, code int) bool {
+	for err != nil {
+		if w, ok := err.(*withCode); ok && w.code == code {
+			return true
+		}
+		switch x := err.(type) {
+		case interface{ Unwrap() error }:
+			err = x.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, e := range x.Unwrap() {
+				if IsCode(e, code) {
+					return true
+				}
+			}
+			return false
+		default:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,43 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMessageIncludesCallerText(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "New keeps the format string",
+			err:  New(UnknownCode, "failed to open file %s", "config.yaml"),
+			want: "failed to open file config.yaml",
+		},
+		{
+			name: "Wrap keeps the wrapped error's message",
+			err:  Wrap(stderrors.New("permission denied"), UnknownCode),
+			want: "permission denied",
+		},
+		{
+			name: "Spawn keeps its own context string",
+			err:  Spawn(New(UnknownCode, "base issue"), UnknownCode, "while loading config"),
+			want: "while loading config",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.err.Error(); !strings.Contains(got, c.want) {
+				t.Errorf("Error() = %q, want it to contain %q", got, c.want)
+			}
+			if got := fmt.Sprintf("%+v", c.err); !strings.Contains(got, c.want) {
+				t.Errorf("%%+v = %q, want it to contain %q", got, c.want)
+			}
+		})
+	}
+}
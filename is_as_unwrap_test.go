@@ -0,0 +1,77 @@
+package errors
+
+import (
+	"io/fs"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	testCoderBase    = 900001
+	testCoderContext = 900002
+)
+
+func init() {
+	MustRegister(testCoder{code: testCoderBase, msg: "base issue"})
+	MustRegister(testCoder{code: testCoderContext, msg: "context issue"})
+}
+
+type testCoder struct {
+	code int
+	msg  string
+}
+
+func (c testCoder) Code() int              { return c.code }
+func (c testCoder) String() string         { return c.msg }
+func (c testCoder) HTTPStatus() int        { return 500 }
+func (c testCoder) GRPCStatus() codes.Code { return codes.Unknown }
+func (c testCoder) Reference() string      { return "" }
+
+func TestUnwrapWalksCauseBeforeErr(t *testing.T) {
+	base := New(testCoderBase, "base issue")
+	spawned := Spawn(base, testCoderContext, "while handling request")
+
+	if got := Unwrap(spawned); got != base {
+		t.Fatalf("Unwrap(spawned) = %v, want the Spawn cause %v", got, base)
+	}
+
+	wrapped := Wrap(fs.ErrNotExist, testCoderBase)
+	if got := Unwrap(wrapped); got != fs.ErrNotExist {
+		t.Fatalf("Unwrap(wrapped) = %v, want %v (no cause set)", got, fs.ErrNotExist)
+	}
+}
+
+func TestIsReachesSpawnCause(t *testing.T) {
+	base := New(testCoderBase, "base issue")
+	spawned := Spawn(base, testCoderContext, "while handling request")
+
+	if !Is(spawned, base) {
+		t.Fatalf("Is(spawned, base) = false, want true: Spawn's cause chain must be reachable")
+	}
+	if !IsCode(spawned, testCoderBase) {
+		t.Fatalf("IsCode(spawned, testCoderBase) = false, want true")
+	}
+	if !IsCode(spawned, testCoderContext) {
+		t.Fatalf("IsCode(spawned, testCoderContext) = false, want true")
+	}
+}
+
+func TestIsReachesStdlibSentinelThroughWrap(t *testing.T) {
+	wrapped := Wrap(fs.ErrNotExist, testCoderBase)
+	if !Is(wrapped, fs.ErrNotExist) {
+		t.Fatalf("Is(wrapped, fs.ErrNotExist) = false, want true")
+	}
+}
+
+func TestAsExtractsRegisteredCoder(t *testing.T) {
+	err := New(testCoderContext, "while handling request")
+
+	var coder Coder
+	if !As(err, &coder) {
+		t.Fatalf("As(err, &coder) = false, want true")
+	}
+	if coder.Code() != testCoderContext {
+		t.Fatalf("coder.Code() = %d, want %d", coder.Code(), testCoderContext)
+	}
+}
@@ -0,0 +1,182 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+)
+
+// jsonStackDepth bounds how many stack frames MarshalJSON and LogValue emit
+// by default, keeping error payloads small in high-volume services. Override
+// it process-wide with SetJSONStackDepth, or per-error with WithMaxStackDepth.
+var jsonStackDepth = 32
+
+// SetJSONStackDepth sets the process-wide default stack depth used by
+// MarshalJSON and LogValue.
+func SetJSONStackDepth(n int) {
+	mu.Lock()
+	defer mu.Unlock()
+	jsonStackDepth = n
+}
+
+// readJSONStackDepth returns the current process-wide default, guarded by mu
+// like the package's other shared state (translator/lang in message()).
+func readJSONStackDepth() int {
+	mu.RLock()
+	defer mu.RUnlock()
+	return jsonStackDepth
+}
+
+// WithMaxStackDepth returns a shallow copy of err with its JSON/slog stack
+// trace output capped at n frames, overriding the process-wide default set
+// by SetJSONStackDepth. err itself is left untouched. It has no effect on
+// errors that are not a *withCode.
+func WithMaxStackDepth(err error, n int) error {
+	w, ok := err.(*withCode)
+	if !ok {
+		return err
+	}
+
+	cp := *w
+	cp.maxStackDepth = &n
+	return &cp
+}
+
+// withCodeJSON is the stable JSON schema for *withCode.
+type withCodeJSON struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Cause   json.RawMessage `json:"cause,omitempty"`
+	Stack   []Frame         `json:"stack,omitempty"`
+}
+
+// MarshalJSON renders w as {"code":...,"message":...,"cause":{...},"stack":
+// [{"func":"pkg.Fn","file":"...","line":42}, ...]}. The cause is expanded
+// recursively by walking Unwrap()/Cause() so wrapped stdlib errors appear in
+// the tree; a *MultiError cause (e.g. from WrapAll) becomes a JSON array of
+// its own errors, each expanded the same way rather than flattened to a
+// string. The stack is capped at jsonStackDepth frames (or the depth set by
+// WithMaxStackDepth).
+func (w *withCode) MarshalJSON() ([]byte, error) {
+	out := withCodeJSON{
+		Code:    w.code,
+		Message: w.message(),
+	}
+
+	if w.stack != nil {
+		depth := readJSONStackDepth()
+		if w.maxStackDepth != nil {
+			depth = *w.maxStackDepth
+		}
+		frames := w.stack.StackTrace()
+		if depth >= 0 && depth < len(frames) {
+			frames = frames[:depth]
+		}
+		out.Stack = frames
+	}
+
+	if next := nextCause(w); next != nil {
+		out.Cause = marshalError(next)
+	}
+
+	return json.Marshal(out)
+}
+
+// nextCause returns the single next error to recurse into, preferring
+// Unwrap() error (which may be err or cause, see withCode.Unwrap) and
+// falling back to Cause() for errors that only implement the older causer
+// interface. The error it returns may itself be a multi-error; callers use
+// marshalError/logValueOf to expand that case.
+func nextCause(err error) error {
+	if u, ok := err.(interface{ Unwrap() error }); ok {
+		if next := u.Unwrap(); next != nil {
+			return next
+		}
+	}
+	if c, ok := err.(interface{ Cause() error }); ok {
+		return c.Cause()
+	}
+	return nil
+}
+
+// marshalError renders err as a JSON value: its own MarshalJSON if it has
+// one, a JSON array of each sub-error (recursively marshaled the same way)
+// if it's a multi-error (Unwrap() []error, e.g. *MultiError), or
+// {"error": err.Error()} as a last resort.
+func marshalError(err error) json.RawMessage {
+	if m, ok := err.(json.Marshaler); ok {
+		if b, mErr := m.MarshalJSON(); mErr == nil {
+			return b
+		}
+	}
+
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		subs := u.Unwrap()
+		parts := make([]json.RawMessage, len(subs))
+		for i, sub := range subs {
+			parts[i] = marshalError(sub)
+		}
+		b, _ := json.Marshal(parts)
+		return b
+	}
+
+	b, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	return b
+}
+
+// LogValue implements slog.LogValuer so log/slog handlers emit structured
+// fields for the code, message and top stack frames without the caller
+// formatting the error to a string first. A *MultiError cause (e.g. from
+// WrapAll) is expanded into an indexed group of its own errors rather than
+// flattened to a string.
+func (w *withCode) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Int("code", w.code),
+		slog.String("message", w.message()),
+	}
+
+	if w.stack != nil {
+		depth := readJSONStackDepth()
+		if w.maxStackDepth != nil {
+			depth = *w.maxStackDepth
+		}
+		frames := w.stack.StackTrace()
+		if depth >= 0 && depth < len(frames) {
+			frames = frames[:depth]
+		}
+		top := make([]string, len(frames))
+		for i, f := range frames {
+			top[i] = f.name()
+		}
+		attrs = append(attrs, slog.Any("stack", top))
+	}
+
+	if next := nextCause(w); next != nil {
+		attrs = append(attrs, slog.Any("cause", logValueOf(next)))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// logValueOf renders err as a slog.Value: its own LogValue if it has one, an
+// indexed group of each sub-error (recursively rendered the same way) if
+// it's a multi-error (Unwrap() []error, e.g. *MultiError), or its Error()
+// string as a last resort.
+func logValueOf(err error) slog.Value {
+	if lv, ok := err.(slog.LogValuer); ok {
+		return lv.LogValue()
+	}
+
+	if u, ok := err.(interface{ Unwrap() []error }); ok {
+		subs := u.Unwrap()
+		attrs := make([]slog.Attr, len(subs))
+		for i, sub := range subs {
+			attrs[i] = slog.Any(fmt.Sprintf("%d", i), logValueOf(sub))
+		}
+		return slog.GroupValue(attrs...)
+	}
+
+	return slog.StringValue(err.Error())
+}
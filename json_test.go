@@ -0,0 +1,150 @@
+package errors
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestMarshalJSONExpandsSpawnCause(t *testing.T) {
+	base := New(testCoderBase, "base issue")
+	spawned := Spawn(base, testCoderContext, "context")
+
+	b, err := json.Marshal(spawned)
+	if err != nil {
+		t.Fatalf("json.Marshal(spawned) error: %v", err)
+	}
+
+	type decoded struct {
+		Code  int             `json:"code"`
+		Cause json.RawMessage `json:"cause,omitempty"`
+	}
+
+	var got decoded
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if got.Code != testCoderContext {
+		t.Fatalf("Code = %d, want %d", got.Code, testCoderContext)
+	}
+	if len(got.Cause) == 0 {
+		t.Fatal("Cause is empty, want the expanded Spawn cause")
+	}
+
+	var cause decoded
+	if err := json.Unmarshal(got.Cause, &cause); err != nil {
+		t.Fatalf("cause is not an expanded withCode: %v (raw: %s)", err, got.Cause)
+	}
+	if cause.Code != testCoderBase {
+		t.Fatalf("cause.Code = %d, want %d (the Spawn base, not the Spawn format string)", cause.Code, testCoderBase)
+	}
+}
+
+func TestLogValueExpandsSpawnCause(t *testing.T) {
+	base := New(testCoderBase, "base issue")
+	spawned := Spawn(base, testCoderContext, "context").(*withCode)
+
+	v := spawned.LogValue()
+	attrs := v.Group()
+
+	var causeAttr slog.Attr
+	found := false
+	for _, a := range attrs {
+		if a.Key == "cause" {
+			causeAttr = a
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("LogValue() has no \"cause\" attribute")
+	}
+	if causeAttr.Value.Kind() != slog.KindGroup {
+		t.Fatalf("cause attribute kind = %v, want a structured group (the expanded Spawn cause), not a string", causeAttr.Value.Kind())
+	}
+}
+
+func TestMarshalJSONExpandsWrapAllCause(t *testing.T) {
+	e1 := New(testCoderBase, "task 1 failed")
+	e2 := New(testCoderContext, "task 2 failed")
+	wrapped := WrapAll([]error{e1, e2}, testCoderWrapAll)
+
+	b, err := json.Marshal(wrapped)
+	if err != nil {
+		t.Fatalf("json.Marshal(wrapped) error: %v", err)
+	}
+
+	type decoded struct {
+		Code  int               `json:"code"`
+		Cause []json.RawMessage `json:"cause,omitempty"`
+	}
+
+	var got decoded
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal error: %v", err)
+	}
+	if len(got.Cause) != 2 {
+		t.Fatalf("len(Cause) = %d, want 2 (one per fanned-out error, not a flattened string); raw: %s", len(got.Cause), b)
+	}
+
+	wantCodes := []int{testCoderBase, testCoderContext}
+	for i, raw := range got.Cause {
+		var sub struct {
+			Code int `json:"code"`
+		}
+		if err := json.Unmarshal(raw, &sub); err != nil {
+			t.Fatalf("cause[%d] is not an expanded withCode: %v (raw: %s)", i, err, raw)
+		}
+		if sub.Code != wantCodes[i] {
+			t.Fatalf("cause[%d].Code = %d, want %d", i, sub.Code, wantCodes[i])
+		}
+	}
+}
+
+func TestLogValueExpandsWrapAllCause(t *testing.T) {
+	e1 := New(testCoderBase, "task 1 failed")
+	e2 := New(testCoderContext, "task 2 failed")
+	wrapped := WrapAll([]error{e1, e2}, testCoderWrapAll).(*withCode)
+
+	var causeAttr slog.Attr
+	found := false
+	for _, a := range wrapped.LogValue().Group() {
+		if a.Key == "cause" {
+			causeAttr = a
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("LogValue() has no \"cause\" attribute")
+	}
+	if causeAttr.Value.Kind() != slog.KindGroup {
+		t.Fatalf("cause attribute kind = %v, want a structured group of the fanned-out errors, not a string", causeAttr.Value.Kind())
+	}
+	if got := len(causeAttr.Value.Group()); got != 2 {
+		t.Fatalf("len(cause group) = %d, want 2 (one per fanned-out error)", got)
+	}
+}
+
+// TestJSONStackDepthConcurrentAccess exercises SetJSONStackDepth racing with
+// MarshalJSON/LogValue reads; run with -race to catch a regression of the
+// unguarded jsonStackDepth read.
+func TestJSONStackDepthConcurrentAccess(t *testing.T) {
+	err := New(testCoderBase, "boom")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			SetJSONStackDepth(i%10 + 1)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if _, mErr := json.Marshal(err); mErr != nil {
+			t.Fatalf("json.Marshal error: %v", mErr)
+		}
+		_ = err.(*withCode).LogValue()
+	}
+	<-done
+
+	SetJSONStackDepth(32)
+}
@@ -0,0 +1,108 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MultiError aggregates multiple errors into a single error value. It
+// implements Unwrap() []error so the Go 1.20 multi-error semantics of
+// errors.Is/errors.As probe every error it contains.
+type MultiError struct {
+	errs []error
+}
+
+// Append combines err with errs into a *MultiError, flattening any nested
+// *MultiError and skipping nils. Append is nil-safe: if err and every entry
+// in errs are nil, it returns nil instead of an empty *MultiError.
+func Append(err error, errs ...error) error {
+	m := &MultiError{}
+	m.add(err)
+	for _, e := range errs {
+		m.add(e)
+	}
+	return m.ErrorOrNil()
+}
+
+func (m *MultiError) add(err error) {
+	if err == nil {
+		return
+	}
+	if me, ok := err.(*MultiError); ok {
+		m.errs = append(m.errs, me.errs...)
+		return
+	}
+	m.errs = append(m.errs, err)
+}
+
+// Errors returns the errors collected in m.
+func (m *MultiError) Errors() []error { return m.errs }
+
+// Len returns the number of errors collected in m.
+func (m *MultiError) Len() int { return len(m.errs) }
+
+// ErrorOrNil returns m if it holds at least one error, or nil otherwise. It
+// lets callers accumulate into a *MultiError across a loop and still return
+// a plain nil error when nothing went wrong.
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || len(m.errs) == 0 {
+		return nil
+	}
+	return m
+}
+
+// Unwrap implements the Go 1.20 multi-error protocol so errors.Is/errors.As
+// probe every error m contains.
+func (m *MultiError) Unwrap() []error { return m.errs }
+
+// Error joins the message of every contained error with "; ".
+func (m *MultiError) Error() string {
+	switch len(m.errs) {
+	case 0:
+		return ""
+	case 1:
+		return m.errs[0].Error()
+	}
+	msgs := make([]string, len(m.errs))
+	for i, e := range m.errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Format implements fmt.Formatter. %+v prints each contained error's stack
+// trace, indented and labeled by index; %s/%v fall back to Error().
+func (m *MultiError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		for i, e := range m.errs {
+			fmt.Fprintf(s, "* error %d:\n", i+1)
+			for _, line := range strings.Split(fmt.Sprintf("%+v", e), "\n") {
+				io.WriteString(s, "\t")
+				io.WriteString(s, line)
+				io.WriteString(s, "\n")
+			}
+		}
+		return
+	}
+	io.WriteString(s, m.Error())
+}
+
+// WrapAll combines errs into a single *withCode carrying code, whose cause
+// is the resulting aggregate. It's useful for fan-out/parallel workflows
+// where a caller needs one error code to represent several independent
+// failures without losing any individual error's stack trace. WrapAll
+// returns nil if errs is empty or contains only nils.
+func WrapAll(errs []error, code int) error {
+	agg := Append(nil, errs...)
+	if agg == nil {
+		return nil
+	}
+
+	return &withCode{
+		err:   fmt.Errorf("%d errors occurred", agg.(*MultiError).Len()),
+		code:  code,
+		cause: agg,
+		stack: callers(),
+	}
+}
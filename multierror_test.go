@@ -0,0 +1,79 @@
+package errors
+
+import (
+	stderrors "errors"
+	"testing"
+)
+
+const testCoderWrapAll = 900003
+
+func init() {
+	MustRegister(testCoder{code: testCoderWrapAll, msg: "fan-out failed"})
+}
+
+func TestAppend(t *testing.T) {
+	e1 := stderrors.New("e1")
+	e2 := stderrors.New("e2")
+	nested := Append(e1, e2)
+
+	cases := []struct {
+		name    string
+		err     error
+		errs    []error
+		wantLen int
+		wantNil bool
+	}{
+		{name: "all nil", err: nil, errs: []error{nil, nil}, wantNil: true},
+		{name: "single error", err: e1, wantLen: 1},
+		{name: "skips nils", err: e1, errs: []error{nil, e2, nil}, wantLen: 2},
+		{name: "flattens nested MultiError", err: nested, errs: []error{e1}, wantLen: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := Append(c.err, c.errs...)
+			if c.wantNil {
+				if got != nil {
+					t.Fatalf("Append(...) = %v, want nil", got)
+				}
+				return
+			}
+			me, ok := got.(*MultiError)
+			if !ok {
+				t.Fatalf("Append(...) = %T, want *MultiError", got)
+			}
+			if me.Len() != c.wantLen {
+				t.Fatalf("Len() = %d, want %d", me.Len(), c.wantLen)
+			}
+		})
+	}
+}
+
+func TestWrapAllReachesEachFannedOutError(t *testing.T) {
+	e1 := New(testCoderBase, "fan-out task 1 failed")
+	e2 := New(testCoderContext, "fan-out task 2 failed")
+
+	wrapped := WrapAll([]error{e1, e2}, testCoderWrapAll)
+	if wrapped == nil {
+		t.Fatal("WrapAll(...) = nil, want a non-nil error")
+	}
+
+	if !Is(wrapped, e1) {
+		t.Fatalf("Is(wrapped, e1) = false, want true: WrapAll's aggregate cause must be reachable")
+	}
+	if !Is(wrapped, e2) {
+		t.Fatalf("Is(wrapped, e2) = false, want true: WrapAll's aggregate cause must be reachable")
+	}
+	if !IsCode(wrapped, testCoderContext) {
+		t.Fatalf("IsCode(wrapped, testCoderContext) = false, want true")
+	}
+}
+
+func TestWrapAllEmpty(t *testing.T) {
+	if got := WrapAll(nil, testCoderBase); got != nil {
+		t.Fatalf("WrapAll(nil, ...) = %v, want nil", got)
+	}
+	if got := WrapAll([]error{nil, nil}, testCoderBase); got != nil {
+		t.Fatalf("WrapAll(all nil, ...) = %v, want nil", got)
+	}
+}